@@ -0,0 +1,80 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sparta
+
+// LambdaFunctionCode is the typed Code property of an AWS::Lambda::Function resource.
+type LambdaFunctionCode struct {
+	S3Bucket string
+	S3Key    string
+}
+
+// LambdaFunctionProperties is the typed Properties bag for an AWS::Lambda::Function resource.
+type LambdaFunctionProperties struct {
+	Code        LambdaFunctionCode
+	Description string `json:",omitempty"`
+	Handler     string
+	MemorySize  int64 `json:",omitempty"`
+	Role        interface{}
+	Runtime     string
+	Timeout     int64 `json:",omitempty"`
+}
+
+// CloudFormationType returns the resource type discriminator for AWS::Lambda::Function.
+func (properties *LambdaFunctionProperties) CloudFormationType() string {
+	return "AWS::Lambda::Function"
+}
+
+// IAMRoleProperties is the typed Properties bag for an AWS::IAM::Role resource.
+type IAMRoleProperties struct {
+	AssumeRolePolicyDocument ArbitraryJSONObject
+	Policies                 []ArbitraryJSONObject `json:",omitempty"`
+}
+
+// CloudFormationType returns the resource type discriminator for AWS::IAM::Role.
+func (properties *IAMRoleProperties) CloudFormationType() string {
+	return "AWS::IAM::Role"
+}
+
+// EventsRuleProperties is the typed Properties bag for an AWS::Events::Rule resource.
+type EventsRuleProperties struct {
+	Description        string                `json:",omitempty"`
+	ScheduleExpression string                `json:",omitempty"`
+	EventPattern       ArbitraryJSONObject   `json:",omitempty"`
+	State              string                `json:",omitempty"`
+	Targets            []ArbitraryJSONObject `json:",omitempty"`
+}
+
+// CloudFormationType returns the resource type discriminator for AWS::Events::Rule.
+func (properties *EventsRuleProperties) CloudFormationType() string {
+	return "AWS::Events::Rule"
+}
+
+// SNSTopicProperties is the typed Properties bag for an AWS::SNS::Topic resource.
+type SNSTopicProperties struct {
+	DisplayName  string                `json:",omitempty"`
+	Subscription []ArbitraryJSONObject `json:",omitempty"`
+	TopicName    string                `json:",omitempty"`
+}
+
+// CloudFormationType returns the resource type discriminator for AWS::SNS::Topic.
+func (properties *SNSTopicProperties) CloudFormationType() string {
+	return "AWS::SNS::Topic"
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// +build !lambdabinary
+
+package sparta
+
+import (
+	"errors"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Delete tears down a previously provisioned service. It confirms the stack
+// exists, issues DeleteStack, polls until the delete reaches a terminal
+// state using the same status-switch pattern as convergeStackState, and
+// then garbage collects the uploaded Lambda ZIP and CloudFormation template
+// objects the deleted stack referenced.
+func Delete(serviceName string, logger *logrus.Logger) error {
+	sess := awsSession(nil, logger)
+	exists, err := stackExists(serviceName, sess, logger)
+	if nil != err {
+		return err
+	}
+	if !exists {
+		logger.Info("Stack does not exist: ", serviceName)
+		return nil
+	}
+
+	awsCloudFormation := cloudformation.New(sess)
+	describeStacksOutput, err := awsCloudFormation.DescribeStacks(&cloudformation.DescribeStacksInput{
+		StackName: aws.String(serviceName),
+	})
+	if nil != err {
+		return err
+	}
+	stackID := *describeStacksOutput.Stacks[0].StackId
+
+	// The uploaded Lambda ZIP is a content-addressed S3 object referenced
+	// from each AWS::Lambda::Function resource's Code property - collect
+	// those keys before the stack (and its resources) disappear.
+	s3Bucket, s3Keys, artifactErr := stackArtifactKeys(stackID, awsCloudFormation)
+	if nil != artifactErr {
+		logger.Warn("Failed to determine uploaded artifact keys: ", artifactErr.Error())
+	}
+	// The uploaded CloudFormation template is content-addressed the same
+	// way but isn't referenced from any resource - it's only recoverable
+	// from the stack's own SpartaTemplateHash parameter.
+	if templateKey := stackTemplateArtifactKey(serviceName, describeStacksOutput.Stacks[0].Parameters); "" != templateKey {
+		s3Keys = append(s3Keys, templateKey)
+	}
+
+	logger.Info("Deleting stack: ", stackID)
+	_, err = awsCloudFormation.DeleteStack(&cloudformation.DeleteStackInput{
+		StackName: aws.String(stackID),
+	})
+	if nil != err {
+		return err
+	}
+
+	stackInfo, err := pollStackOperationCompletion(stackID, awsCloudFormation, logger)
+	if nil != err {
+		return err
+	}
+	if nil != stackInfo && *stackInfo.StackStatus != cloudformation.StackStatusDeleteComplete {
+		return errors.New("Failed to delete stack: " + serviceName)
+	}
+	logger.Info("Stack deleted: ", serviceName)
+
+	if "" != s3Bucket {
+		s3Client := s3.New(sess)
+		for _, eachKey := range s3Keys {
+			logger.Info("Deleting uploaded artifact: ", eachKey)
+			_, deleteErr := s3Client.DeleteObject(&s3.DeleteObjectInput{
+				Bucket: aws.String(s3Bucket),
+				Key:    aws.String(eachKey),
+			})
+			if nil != deleteErr {
+				logger.Warn("Failed to delete artifact: ", eachKey)
+			}
+		}
+	}
+	return nil
+}
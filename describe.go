@@ -0,0 +1,190 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// +build !lambdabinary
+
+package sparta
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// describeLambdaFunction is the per-function data the describe report template renders.
+type describeLambdaFunction struct {
+	LogicalID      string
+	FunctionARN    string
+	RoleARN        string
+	RoleConsoleURL string
+	EventSources   []string
+}
+
+// Describe paginates the deployed stack's resources, filters the
+// AWS::Lambda::Function entries, and renders a self-contained HTML report
+// with per-function ARNs, IAM role links, and event source bindings so
+// users can audit a deployed service without leaving the CLI.
+func Describe(serviceName string, out io.Writer, logger *logrus.Logger) error {
+	awsCloudFormation := cloudformation.New(awsSession(nil, logger))
+
+	resources, err := listStackResources(serviceName, awsCloudFormation)
+	if nil != err {
+		return err
+	}
+
+	stackTemplate, err := currentStackTemplate(serviceName, awsCloudFormation)
+	if nil != err {
+		return err
+	}
+
+	lambdaFunctions := make([]describeLambdaFunction, 0)
+	for _, eachResource := range resources {
+		if *eachResource.ResourceType != "AWS::Lambda::Function" {
+			continue
+		}
+		functionARN := ""
+		if nil != eachResource.PhysicalResourceId {
+			functionARN = *eachResource.PhysicalResourceId
+		}
+		roleARN, eventSources, describeErr := describeLambdaResource(stackTemplate,
+			*eachResource.LogicalResourceId)
+		if nil != describeErr {
+			logger.Warn("Failed to describe resource: ", *eachResource.LogicalResourceId, " ", describeErr.Error())
+		}
+		lambdaFunctions = append(lambdaFunctions, describeLambdaFunction{
+			LogicalID:      *eachResource.LogicalResourceId,
+			FunctionARN:    functionARN,
+			RoleARN:        roleARN,
+			RoleConsoleURL: iamRoleConsoleURL(roleARN),
+			EventSources:   eventSources,
+		})
+	}
+
+	reportHTML := FSMustString(false, "/resources/describe.html")
+	reportTemplate, err := template.New("describe").Parse(reportHTML)
+	if nil != err {
+		return err
+	}
+	return reportTemplate.Execute(out, struct {
+		ServiceName string
+		Functions   []describeLambdaFunction
+	}{
+		ServiceName: serviceName,
+		Functions:   lambdaFunctions,
+	})
+}
+
+// listStackResources pages through ListStackResources and returns every
+// resource summary for stackName.
+func listStackResources(stackName string, awsCloudFormation *cloudformation.CloudFormation) ([]*cloudformation.StackResourceSummary, error) {
+	resources := make([]*cloudformation.StackResourceSummary, 0)
+	nextToken := ""
+	for {
+		params := &cloudformation.ListStackResourcesInput{
+			StackName: aws.String(stackName),
+		}
+		if len(nextToken) > 0 {
+			params.NextToken = aws.String(nextToken)
+		}
+		resp, err := awsCloudFormation.ListStackResources(params)
+		if nil != err {
+			return nil, err
+		}
+		resources = append(resources, resp.StackResourceSummaries...)
+		if nil == resp.NextToken {
+			break
+		}
+		nextToken = *resp.NextToken
+	}
+	return resources, nil
+}
+
+// rawStackTemplate is the subset of a CloudFormation template's JSON body
+// that describeLambdaResource needs to resolve role ARNs and event source
+// dependencies.
+type rawStackTemplate struct {
+	Resources map[string]struct {
+		Type       string
+		Properties struct {
+			Role interface{}
+		}
+		DependsOn []string
+	}
+}
+
+// currentStackTemplate fetches and parses stackName's current template once
+// so callers can resolve per-resource details without re-fetching it.
+func currentStackTemplate(stackName string, awsCloudFormation *cloudformation.CloudFormation) (*rawStackTemplate, error) {
+	templateOutput, err := awsCloudFormation.GetTemplate(&cloudformation.GetTemplateInput{
+		StackName: aws.String(stackName),
+	})
+	if nil != err {
+		return nil, err
+	}
+	var rawTemplate rawStackTemplate
+	if err := json.Unmarshal([]byte(*templateOutput.TemplateBody), &rawTemplate); nil != err {
+		return nil, err
+	}
+	return &rawTemplate, nil
+}
+
+// describeLambdaResource resolves a Lambda function's execution role ARN and
+// the event source bindings declared for it in the stack's current template.
+func describeLambdaResource(rawTemplate *rawStackTemplate, logicalID string) (string, []string, error) {
+	resource, exists := rawTemplate.Resources[logicalID]
+	if !exists {
+		return "", nil, fmt.Errorf("resource not found in template: %s", logicalID)
+	}
+	roleARN := fmt.Sprintf("%v", resource.Properties.Role)
+
+	eventSources := make([]string, 0)
+	for eachLogicalID, eachResource := range rawTemplate.Resources {
+		if eachResource.Type == "AWS::Lambda::Function" {
+			continue
+		}
+		for _, eachDependency := range eachResource.DependsOn {
+			if eachDependency == logicalID {
+				eventSources = append(eventSources, fmt.Sprintf("%s (%s)", eachLogicalID, eachResource.Type))
+			}
+		}
+	}
+	return roleARN, eventSources, nil
+}
+
+// iamRoleConsoleURL returns the AWS console deep link for an IAM role ARN so
+// the HTML report can link directly to it. The console keys role links off
+// the role name, not the ARN, so the name is extracted from the trailing
+// path component of the ARN's resource segment.
+func iamRoleConsoleURL(roleARN string) string {
+	if "" == roleARN {
+		return ""
+	}
+	roleName := roleARN
+	if slashIndex := strings.LastIndex(roleARN, "/"); slashIndex != -1 {
+		roleName = roleARN[slashIndex+1:]
+	}
+	return fmt.Sprintf("https://console.aws.amazon.com/iam/home#/roles/%s", roleName)
+}
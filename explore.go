@@ -0,0 +1,124 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sparta
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// dispatchBody is the shape POSTed to the Explore dispatch endpoint: a
+// Lambda event body paired with the invocation context AWS would normally
+// supply.
+type dispatchBody struct {
+	Context json.RawMessage `json:"context"`
+	Event   json.RawMessage `json:"event"`
+}
+
+// dispatchHandler routes a POST against /<lambdaFnName> to the matching
+// handler in dispatchMap, entirely in-process - no AWS round trip.
+func dispatchHandler(dispatchMap map[string]*LambdaAWSInfo, logger *logrus.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lambdaFnName := strings.TrimPrefix(r.URL.Path, "/")
+		lambdaInfo, exists := dispatchMap[lambdaFnName]
+		if !exists || nil == lambdaInfo.handlerSymbol {
+			http.Error(w, fmt.Sprintf("No handler registered for: %s", lambdaFnName), http.StatusNotFound)
+			return
+		}
+		var body dispatchBody
+		if err := json.NewDecoder(r.Body).Decode(&body); nil != err {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lambdaContext := &LambdaContext{FunctionName: lambdaFnName}
+		if len(body.Context) != 0 {
+			if err := json.Unmarshal(body.Context, lambdaContext); nil != err {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		logger.WithFields(logrus.Fields{
+			"LambdaFunction": lambdaFnName,
+		}).Info("Dispatching request")
+		lambdaInfo.handlerSymbol(&body.Event, lambdaContext, w, logger)
+	}
+}
+
+// Explore boots the localhost HTTP dispatch server that Execute uses to
+// route Lambda invocations to their handlers in-process. It's what backs
+// both the interactive `explore` CLI subcommand and the endpoint the NodeJS
+// proxy calls when this binary is actually running inside Lambda.
+func Explore(lambdaAWSInfos []*LambdaAWSInfo, port int, logger *logrus.Logger) (*http.Server, error) {
+	dispatchMap := make(map[string]*LambdaAWSInfo, len(lambdaAWSInfos))
+	for _, eachLambda := range lambdaAWSInfos {
+		dispatchMap[eachLambda.lambdaFnName] = eachLambda
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", dispatchHandler(dispatchMap, logger))
+	server := &http.Server{
+		Addr:    fmt.Sprintf("127.0.0.1:%d", port),
+		Handler: mux,
+	}
+	listener, err := net.Listen("tcp", server.Addr)
+	if nil != err {
+		return nil, err
+	}
+	go func() {
+		logger.Info("Explore dispatch endpoint listening: ", server.Addr)
+		serveErr := server.Serve(listener)
+		if nil != serveErr && serveErr != http.ErrServerClosed {
+			logger.Error("Dispatch endpoint stopped: ", serveErr.Error())
+		}
+	}()
+	return server, nil
+}
+
+// Execute starts the dispatch endpoint and blocks forever. When
+// parentProcessPID is non-zero (the NodeJS proxy spawned this process),
+// Execute signals SIGUSR2 once the endpoint is listening so the shim knows
+// the Go child is ready to accept requests.
+func Execute(lambdaAWSInfos []*LambdaAWSInfo, port int, parentProcessPID int, logger *logrus.Logger) error {
+	server, err := Explore(lambdaAWSInfos, port, logger)
+	if nil != err {
+		return err
+	}
+	defer server.Close()
+
+	if parentProcessPID != 0 {
+		parentProcess, findErr := os.FindProcess(parentProcessPID)
+		if nil != findErr {
+			return findErr
+		}
+		if signalErr := parentProcess.Signal(syscall.SIGUSR2); nil != signalErr {
+			return signalErr
+		}
+		logger.Debug("Signaled parent process ready: ", parentProcessPID)
+	}
+	select {}
+}
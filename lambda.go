@@ -0,0 +1,107 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sparta
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// TemplateDecorator is the extension point that lets a Lambda function
+// contribute additional typed resources or outputs to the CloudFormation
+// template Sparta generates for it, beyond the AWS::Lambda::Function resource
+// Sparta creates automatically. The function's execution role is an existing
+// IAM role referenced by ARN; Sparta adds no AWS::IAM::Role resource for it.
+type TemplateDecorator func(serviceName string,
+	lambdaResourceName string,
+	lambdaResource *LambdaFunctionProperties,
+	S3Bucket string,
+	S3Key string,
+	template *Template,
+	logger *logrus.Logger) error
+
+// LambdaAWSInfo stores the metadata Sparta needs to package, provision, and
+// register a single AWS Lambda function as part of a service.
+type LambdaAWSInfo struct {
+	lambdaFnName      string
+	handlerSymbol     LambdaFunction
+	ExecutionRoleName string
+	Description       string
+	MemorySize        int64
+	Timeout           int64
+	Decorator         TemplateDecorator
+}
+
+// NewLambdaAWSInfo returns a LambdaAWSInfo bound to fn and registered under
+// lambdaFnName, ready to be provisioned with the given IAM execution role.
+func NewLambdaAWSInfo(lambdaFnName string, fn LambdaFunction, executionRoleName string) *LambdaAWSInfo {
+	return &LambdaAWSInfo{
+		lambdaFnName:      lambdaFnName,
+		handlerSymbol:     fn,
+		ExecutionRoleName: executionRoleName,
+	}
+}
+
+// toCloudFormationResources adds the typed AWS::Lambda::Function resource
+// this Lambda needs to template, then runs the TemplateDecorator (if any)
+// so callers can layer their own typed resources/outputs onto the stack.
+func (info *LambdaAWSInfo) toCloudFormationResources(serviceName string,
+	S3Bucket string,
+	S3Key string,
+	lambdaIAMRoleNameMap map[string]string,
+	template *Template,
+	logger *logrus.Logger) error {
+
+	roleArn, exists := lambdaIAMRoleNameMap[info.ExecutionRoleName]
+	if !exists {
+		return fmt.Errorf("IAM role ARN not resolved for execution role: %s", info.ExecutionRoleName)
+	}
+
+	lambdaResourceName := sanitizedName(info.lambdaFnName)
+	lambdaProperties := &LambdaFunctionProperties{
+		Code: LambdaFunctionCode{
+			S3Bucket: S3Bucket,
+			S3Key:    S3Key,
+		},
+		Description: info.Description,
+		Handler:     fmt.Sprintf("index.%s", lambdaResourceName),
+		MemorySize:  info.MemorySize,
+		Role:        roleArn,
+		Runtime:     "nodejs",
+		Timeout:     info.Timeout,
+	}
+	template.AddResource(lambdaResourceName, lambdaProperties)
+
+	if info.Decorator != nil {
+		decoratorErr := info.Decorator(serviceName,
+			lambdaResourceName,
+			lambdaProperties,
+			S3Bucket,
+			S3Key,
+			template,
+			logger)
+		if decoratorErr != nil {
+			return fmt.Errorf("TemplateDecorator for %s failed: %s", lambdaResourceName, decoratorErr.Error())
+		}
+	}
+	return nil
+}
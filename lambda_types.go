@@ -0,0 +1,47 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sparta
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// LambdaContext mirrors the invocation metadata AWS Lambda would normally
+// supply to a handler, whether the call actually came from AWS or from the
+// local Explore dispatch shim.
+type LambdaContext struct {
+	AWSRequestID      string `json:"aws_request_id"`
+	InvokeID          string `json:"invoke_id"`
+	LogGroupName      string `json:"log_group_name"`
+	LogStreamName     string `json:"log_stream_name"`
+	FunctionName      string `json:"function_name"`
+	RemainingTimeInMs int64  `json:"remaining_time_in_millis"`
+}
+
+// LambdaFunction is the signature every Sparta-managed Lambda handler
+// implements. The handler writes its response to w; Sparta's dispatch shim
+// (in-process for Explore, over HTTP from the NodeJS proxy when compiled
+// with the lambdabinary tag) takes care of getting bytes to and from the
+// caller.
+type LambdaFunction func(event *json.RawMessage, context *LambdaContext, w http.ResponseWriter, logger *logrus.Logger)
@@ -0,0 +1,91 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// +build !lambdabinary
+
+package sparta
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+// stepDuration records how long a single named workflowStep took to run, in
+// the order the steps ran.
+type stepDuration struct {
+	Step       string
+	DurationMS int64
+}
+
+// stepTimer wraps step so that its wall-clock duration is recorded on the
+// workflowContext and, when the step succeeds, emitted as a structured
+// field-log record tagged with stepName, alongside whatever fields the step
+// itself logs.
+func stepTimer(stepName string, step workflowStep) workflowStep {
+	return func(ctx *workflowContext) (workflowStep, error) {
+		start := time.Now()
+		next, err := step(ctx)
+		durationMS := time.Since(start).Nanoseconds() / int64(time.Millisecond)
+		ctx.stepDurations = append(ctx.stepDurations, stepDuration{Step: stepName, DurationMS: durationMS})
+		if nil == err {
+			ctx.logger.WithFields(logrus.Fields{
+				"ServiceName": ctx.serviceName,
+				"Step":        stepName,
+				"DurationMS":  durationMS,
+			}).Info("Step complete")
+		}
+		return next, err
+	}
+}
+
+// publishDeployMetrics emits the Sparta/Deploy CloudWatch custom metrics for
+// a completed Provision run so users driving CI can alarm on regressions.
+// Publishing is best-effort: a failure here is logged and otherwise
+// ignored, since metrics are a nice-to-have and shouldn't fail an otherwise
+// successful deploy.
+func publishDeployMetrics(ctx *workflowContext, totalDurationMS int64) {
+	cloudWatchClient := cloudwatch.New(ctx.awsSession)
+	metricData := []*cloudwatch.MetricDatum{
+		deployMetricDatum("Duration", float64(totalDurationMS), cloudwatch.StandardUnitMilliseconds),
+		deployMetricDatum("BinarySizeBytes", float64(ctx.binarySizeBytes), cloudwatch.StandardUnitBytes),
+		deployMetricDatum("TemplateBytes", float64(ctx.templateSizeBytes), cloudwatch.StandardUnitBytes),
+	}
+	_, err := cloudWatchClient.PutMetricData(&cloudwatch.PutMetricDataInput{
+		Namespace:  aws.String("Sparta/Deploy"),
+		MetricData: metricData,
+	})
+	if nil != err {
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+		}).Warn("Failed to publish deploy metrics: ", err.Error())
+	}
+}
+
+// deployMetricDatum builds a single Sparta/Deploy CloudWatch MetricDatum.
+func deployMetricDatum(name string, value float64, unit string) *cloudwatch.MetricDatum {
+	return &cloudwatch.MetricDatum{
+		MetricName: aws.String(name),
+		Value:      aws.Float64(value),
+		Unit:       aws.String(unit),
+	}
+}
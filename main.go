@@ -0,0 +1,69 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// +build !lambdabinary
+
+package sparta
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Main is the entry point a Sparta service's own main() calls. It parses
+// the CLI subcommand (provision, execute, explore, delete, describe) and
+// drives the matching workflow.
+func Main(serviceName string, serviceDescription string, lambdaAWSInfos []*LambdaAWSInfo, s3Bucket string) error {
+	if len(os.Args) < 2 {
+		return errors.New("Usage: " + os.Args[0] + " [provision|execute|explore|delete|describe]")
+	}
+	logger := logrus.New()
+	switch os.Args[1] {
+	case "provision":
+		return Provision(serviceName, serviceDescription, lambdaAWSInfos, s3Bucket, nil, logger)
+	case "delete":
+		return Delete(serviceName, logger)
+	case "describe":
+		flagSet := flag.NewFlagSet("describe", flag.ExitOnError)
+		outputPath := flagSet.String("out", "", "HTML report output path (defaults to stdout)")
+		flagSet.Parse(os.Args[2:])
+		out := os.Stdout
+		if "" != *outputPath {
+			reportFile, err := os.Create(*outputPath)
+			if nil != err {
+				return err
+			}
+			defer reportFile.Close()
+			out = reportFile
+		}
+		return Describe(serviceName, out, logger)
+	case "execute", "explore":
+		flagSet := flag.NewFlagSet("explore", flag.ExitOnError)
+		port := flagSet.Int("port", 9999, "Local dispatch port")
+		flagSet.Parse(os.Args[2:])
+		return Execute(lambdaAWSInfos, *port, 0, logger)
+	default:
+		return fmt.Errorf("Unknown command: %s", os.Args[1])
+	}
+}
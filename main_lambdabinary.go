@@ -0,0 +1,46 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// +build lambdabinary
+
+package sparta
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Main is the entry point when this binary is compiled with the
+// lambdabinary tag and running inside AWS Lambda. There's no subcommand to
+// parse here: the NodeJS proxy always execs this binary directly and
+// expects it to start serving the dispatch endpoint the shim was told to
+// call, so we read that endpoint's configuration from the environment the
+// shim set up instead.
+func Main(serviceName string, serviceDescription string, lambdaAWSInfos []*LambdaAWSInfo, s3Bucket string) error {
+	logger := logrus.New()
+	port, _ := strconv.Atoi(os.Getenv("SPARTA_DISPATCH_PORT"))
+	if 0 == port {
+		port = 9999
+	}
+	parentProcessPID, _ := strconv.Atoi(os.Getenv("SPARTA_PARENT_PID"))
+	return Execute(lambdaAWSInfos, port, parentProcessPID, logger)
+}
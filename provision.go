@@ -24,55 +24,56 @@ package sparta
 
 import (
 	"archive/zip"
-	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/Sirupsen/logrus"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
 
 type workflowContext struct {
-	serviceName          string
-	serviceDescription   string
-	lambdaAWSInfos       []*LambdaAWSInfo
-	lambdaIAMRoleNameMap map[string]string
-	s3Bucket             string
-	s3LambdaZipKey       string
-	logger               *logrus.Logger
+	serviceName            string
+	serviceDescription     string
+	lambdaAWSInfos         []*LambdaAWSInfo
+	lambdaIAMRoleNameMap   map[string]string
+	s3Bucket               string
+	s3LambdaZipKey         string
+	s3LambdaZipKeyUploaded bool
+	templateHash           string
+	stackCapabilities      []*string
+	logger                 *logrus.Logger
+	awsSession             *session.Session
+	stepDurations          []stepDuration
+	binarySizeBytes        int64
+	templateSizeBytes      int64
 }
 
 type workflowStep func(ctx *workflowContext) (workflowStep, error)
 
-/*
-Return an AWS configuration option configured from the command line
-http://docs.aws.amazon.com/cli/latest/userguide/cli-chap-getting-started.html
-*/
-func awsConfig() *aws.Config {
-	region := os.Getenv("AWS_DEFAULT_REGION")
-	if "" == region {
-		region = "us-east-1"
-	}
-	return aws.NewConfig().WithRegion(region).WithMaxRetries(3)
-}
-
 // Verify & cache the IAM rolename to ARN mapping
 func verifyIAMRoles(ctx *workflowContext) (workflowStep, error) {
-	ctx.logger.Info("Verifying IAM Lambda execution roles")
+	ctx.logger.WithFields(logrus.Fields{
+		"ServiceName": ctx.serviceName,
+		"Step":        "VerifyIAMRoles",
+	}).Info("Verifying IAM Lambda execution roles")
 	ctx.lambdaIAMRoleNameMap = make(map[string]string, 0)
-	svc := iam.New(awsConfig())
+	svc := iam.New(ctx.awsSession)
 
 	for _, eachLambda := range ctx.lambdaAWSInfos {
 		_, exists := ctx.lambdaIAMRoleNameMap[eachLambda.ExecutionRoleName]
@@ -81,7 +82,10 @@ func verifyIAMRoles(ctx *workflowContext) (workflowStep, error) {
 			params := &iam.GetRoleInput{
 				RoleName: aws.String(eachLambda.ExecutionRoleName),
 			}
-			ctx.logger.Debug("Checking IAM RoleName: ", eachLambda.ExecutionRoleName)
+			ctx.logger.WithFields(logrus.Fields{
+				"ServiceName": ctx.serviceName,
+				"LogicalID":   eachLambda.ExecutionRoleName,
+			}).Debug("Checking IAM RoleName")
 			resp, err := svc.GetRole(params)
 			if err != nil {
 				ctx.logger.Error(err.Error())
@@ -92,8 +96,12 @@ func verifyIAMRoles(ctx *workflowContext) (workflowStep, error) {
 			ctx.lambdaIAMRoleNameMap[eachLambda.ExecutionRoleName] = *resp.Role.Arn
 		}
 	}
-	ctx.logger.Info("IAM roles verified. Count: ", len(ctx.lambdaIAMRoleNameMap))
-	return createPackageStep(), nil
+	ctx.logger.WithFields(logrus.Fields{
+		"ServiceName": ctx.serviceName,
+		"Step":        "VerifyIAMRoles",
+		"Count":       len(ctx.lambdaIAMRoleNameMap),
+	}).Info("IAM roles verified")
+	return stepTimer("Package", createPackageStep()), nil
 }
 
 // Return a string representation of a JS function call that can be exposed
@@ -142,10 +150,16 @@ func createPackageStep() workflowStep {
 		sanitizedServiceName := sanitizedName(ctx.serviceName)
 		executableOutput := fmt.Sprintf("%s.lambda.amd64", sanitizedServiceName)
 		cmd := exec.Command("go", "build", "-o", executableOutput, "-tags", "lambdabinary", ".")
-		ctx.logger.Debug("Building application binary: ", cmd.Args)
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"Step":        "Package",
+		}).Debug("Building application binary: ", cmd.Args)
 		cmd.Env = os.Environ()
 		cmd.Env = append(cmd.Env, "GOOS=linux", "GOARCH=amd64", "GO15VENDOREXPERIMENT=1")
-		ctx.logger.Info("Compiling binary: ", executableOutput)
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"Step":        "Package",
+		}).Info("Compiling binary: ", executableOutput)
 
 		outputWriter := ctx.logger.Writer()
 		defer outputWriter.Close()
@@ -166,7 +180,12 @@ func createPackageStep() workflowStep {
 		}
 		// Minimum hello world size is 2.3M
 		// Minimum HTTP hello world is 6.3M
-		ctx.logger.Debug("Executable binary size (MB): ", stat.Size()/(1024*1024))
+		ctx.binarySizeBytes = stat.Size()
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName":     ctx.serviceName,
+			"Step":            "Package",
+			"BinarySizeBytes": ctx.binarySizeBytes,
+		}).Debug("Executable binary size")
 
 		workingDir, err := os.Getwd()
 		if err != nil {
@@ -181,28 +200,11 @@ func createPackageStep() workflowStep {
 			tmpFile.Close()
 		}()
 
-		ctx.logger.Info("Creating ZIP archive for upload: ", tmpFile.Name())
-		lambdaArchive := zip.NewWriter(tmpFile)
-		defer lambdaArchive.Close()
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"Step":        "Package",
+		}).Info("Creating ZIP archive for upload: ", tmpFile.Name())
 
-		// File info for the binary executable
-		binaryWriter, err := lambdaArchive.Create(filepath.Base(executableOutput))
-		if err != nil {
-			return nil, errors.New("Failed to create ZIP entry: " + filepath.Base(executableOutput))
-		}
-		reader, err := os.Open(executableOutput)
-		if err != nil {
-			return nil, errors.New("Failed to open file: " + executableOutput)
-		}
-		defer reader.Close()
-		io.Copy(binaryWriter, reader)
-
-		// Add the string literal adapter, which requires us to add exported
-		// functions to the end of index.js
-		nodeJSWriter, err := lambdaArchive.Create("index.js")
-		if err != nil {
-			return nil, errors.New("Failed to create ZIP entry: index.js")
-		}
 		nodeJSSource := FSMustString(false, "/resources/index.js")
 		nodeJSSource += "// DO NOT EDIT - CONTENT UNTIL EOF IS AUTOMATICALLY GENERATED\n"
 		for _, eachLambda := range ctx.lambdaAWSInfos {
@@ -213,35 +215,84 @@ func createPackageStep() workflowStep {
 		// with the service binary name
 		nodeJSSource += fmt.Sprintf("SPARTA_BINARY_NAME='%s';\n", executableOutput)
 		ctx.logger.Debug("Dynamically generated NodeJS adapter:\n", nodeJSSource)
-		stringReader := strings.NewReader(nodeJSSource)
-		io.Copy(nodeJSWriter, stringReader)
+
+		binaryReader, err := os.Open(executableOutput)
+		if err != nil {
+			return nil, errors.New("Failed to open file: " + executableOutput)
+		}
+		defer binaryReader.Close()
+
+		// Sort the entries by name so that two builds with identical inputs
+		// always produce byte-identical ZIP archives, which is what lets the
+		// SHA-256 of the archive double as a stable, content-addressed S3 key.
+		zipEntries := []struct {
+			name   string
+			reader io.Reader
+		}{
+			{filepath.Base(executableOutput), binaryReader},
+			{"index.js", strings.NewReader(nodeJSSource)},
+		}
+		sort.Slice(zipEntries, func(i, j int) bool { return zipEntries[i].name < zipEntries[j].name })
+
+		contentHash := sha256.New()
+		lambdaArchive := zip.NewWriter(io.MultiWriter(tmpFile, contentHash))
+		for _, eachEntry := range zipEntries {
+			header := &zip.FileHeader{Name: eachEntry.name, Method: zip.Deflate}
+			header.SetModTime(time.Time{})
+			entryWriter, err := lambdaArchive.CreateHeader(header)
+			if err != nil {
+				return nil, errors.New("Failed to create ZIP entry: " + eachEntry.name)
+			}
+			if _, err := io.Copy(entryWriter, eachEntry.reader); err != nil {
+				return nil, errors.New("Failed to write ZIP entry: " + eachEntry.name)
+			}
+		}
+		if err := lambdaArchive.Close(); err != nil {
+			return nil, errors.New("Failed to finalize ZIP archive")
+		}
 		// TODO: Zip template
-		return createUploadStep(tmpFile.Name()), nil
+		return stepTimer("Upload", createUploadStep(tmpFile.Name(), hex.EncodeToString(contentHash.Sum(nil)))), nil
 	}
 }
 
-// Upload the
-func createUploadStep(packagePath string) workflowStep {
+// Upload the ZIP archive under a content-addressed key so that identical
+// builds reuse the same S3 object across deploys.
+func createUploadStep(packagePath string, contentHash string) workflowStep {
 	return func(ctx *workflowContext) (workflowStep, error) {
-		ctx.logger.Info("Uploading ZIP archive to S3")
+		keyName := fmt.Sprintf("sha256-%s.zip", contentHash)
+		ctx.s3LambdaZipKey = keyName
 
-		reader, err := os.Open(packagePath)
-		if err != nil {
-			return nil, errors.New("Failed to upload to S3: " + err.Error())
-		}
-		defer func() {
-			reader.Close()
+		s3Client := s3.New(ctx.awsSession)
+		_, headErr := s3Client.HeadObject(&s3.HeadObjectInput{
+			Bucket: aws.String(ctx.s3Bucket),
+			Key:    aws.String(keyName),
+		})
+		if nil == headErr {
+			ctx.logger.WithFields(logrus.Fields{
+				"ServiceName": ctx.serviceName,
+				"Step":        "Upload",
+				"S3Key":       keyName,
+			}).Info("Lambda ZIP unchanged, skipping upload")
 			os.Remove(packagePath)
-		}()
+			return stepTimer("CloudFormation", ensureCloudFormationStack(keyName)), nil
+		}
 
-		s3Client := s3.New(awsConfig())
-		uploadOptions := &s3manager.UploadOptions{S3: s3Client}
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"Step":        "Upload",
+			"S3Key":       keyName,
+		}).Info("Uploading ZIP archive to S3")
 
 		body, err := os.Open(packagePath)
 		if nil != err {
 			return nil, err
 		}
-		keyName := filepath.Base(packagePath)
+		defer func() {
+			body.Close()
+			os.Remove(packagePath)
+		}()
+
+		uploadOptions := &s3manager.UploadOptions{S3: s3Client}
 		uploadInput := &s3manager.UploadInput{
 			Bucket:      &ctx.s3Bucket,
 			Key:         &keyName,
@@ -253,16 +304,22 @@ func createUploadStep(packagePath string) workflowStep {
 		if nil != err {
 			return nil, err
 		}
-		ctx.logger.Info("ZIP archive uploaded: ", result.Location)
-		// Cache it in case there was an error & we need to cleanup
-		ctx.s3LambdaZipKey = keyName
-		return ensureCloudFormationStack(keyName), nil
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"Step":        "Upload",
+			"S3Key":       keyName,
+		}).Info("ZIP archive uploaded: ", result.Location)
+		// Only mark the artifact as "uploaded by this run" once the PUT
+		// actually happens, so Provision's failure-path cleanup never
+		// deletes a pre-existing, content-addressed object it didn't create.
+		ctx.s3LambdaZipKeyUploaded = true
+		return stepTimer("CloudFormation", ensureCloudFormationStack(keyName)), nil
 	}
 }
 
 // Does a given stack exist?
-func stackExists(stackNameOrID string, logger *logrus.Logger) (bool, error) {
-	awsCloudFormation := cloudformation.New(awsConfig())
+func stackExists(stackNameOrID string, sess *session.Session, logger *logrus.Logger) (bool, error) {
+	awsCloudFormation := cloudformation.New(sess)
 	describeStacksInput := &cloudformation.DescribeStacksInput{
 		StackName: aws.String(stackNameOrID),
 	}
@@ -282,26 +339,206 @@ func stackExists(stackNameOrID string, logger *logrus.Logger) (bool, error) {
 	return exists, nil
 }
 
-func convergeStackState(cfTemplateURL string, ctx *workflowContext) (*cloudformation.Stack, error) {
+// deployParametersUnchanged reports whether existing and desired hold the
+// same number of parameters and every key/value in desired is present in
+// existing, which lets convergeStackState recognize a
+// no-op deploy (identical ZIP and template content) and skip UpdateStack.
+func deployParametersUnchanged(existing []*cloudformation.Parameter, desired []*cloudformation.Parameter) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	existingValues := make(map[string]string, len(existing))
+	for _, eachParam := range existing {
+		existingValues[*eachParam.ParameterKey] = *eachParam.ParameterValue
+	}
+	for _, eachParam := range desired {
+		value, exists := existingValues[*eachParam.ParameterKey]
+		if !exists || value != *eachParam.ParameterValue {
+			return false
+		}
+	}
+	return true
+}
 
-	// Does it exist?
-	exists, err := stackExists(ctx.serviceName, ctx.logger)
+// validateTemplateAndPermissions runs the cheap checks CloudFormation would
+// otherwise only report minutes later, at the end of a ROLLBACK_COMPLETE
+// poll cycle: template validity/capabilities via ValidateTemplate, and the
+// deploying principal's IAM permissions via SimulatePrincipalPolicy. Any
+// deploy Capabilities ValidateTemplate reports are cached on ctx so
+// convergeStackState can pass them through to Create/UpdateStack.
+func validateTemplateAndPermissions(templateURL string, ctx *workflowContext) error {
+	awsCloudFormation := cloudformation.New(ctx.awsSession)
+	validateOutput, err := awsCloudFormation.ValidateTemplate(&cloudformation.ValidateTemplateInput{
+		TemplateURL: aws.String(templateURL),
+	})
 	if nil != err {
-		return nil, err
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"Step":        "CloudFormation",
+		}).Error("Template validation failed: ", err.Error())
+		return err
+	}
+	if len(validateOutput.Capabilities) != 0 {
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName":        ctx.serviceName,
+			"Step":               "CloudFormation",
+			"Capabilities":       aws.StringValueSlice(validateOutput.Capabilities),
+			"CapabilitiesReason": aws.StringValue(validateOutput.CapabilitiesReason),
+		}).Info("Template requires acknowledged capabilities")
+	}
+	ctx.stackCapabilities = validateOutput.Capabilities
+
+	return simulateDeployPermissions(ctx)
+}
+
+// simulateDeployPermissions calls iam.SimulatePrincipalPolicy for the
+// CloudFormation, Lambda, s3:PutObject and iam:PassRole actions a deploy
+// depends on, whether Provision calls them itself or CloudFormation issues
+// them on the caller's behalf, and
+// fails fast with a consolidated report if any of them would be denied
+// rather than letting the caller discover it from a failed stack event.
+func simulateDeployPermissions(ctx *workflowContext) error {
+	callerIdentity, err := sts.New(ctx.awsSession).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if nil != err {
+		return err
+	}
+	policySourceArn := iamPolicySourceArn(*callerIdentity.Arn)
+
+	type permissionCheck struct {
+		actionNames  []string
+		resourceArns []string
+	}
+	checks := []permissionCheck{
+		{actionNames: []string{
+			"cloudformation:CreateStack",
+			"cloudformation:UpdateStack",
+			"cloudformation:DescribeStacks",
+			"cloudformation:DescribeStackEvents",
+			"cloudformation:DescribeStackResources",
+			"cloudformation:ValidateTemplate",
+			"cloudformation:GetTemplate",
+			"cloudformation:ListStackResources",
+		}},
+		{actionNames: []string{
+			"lambda:CreateFunction",
+			"lambda:UpdateFunctionCode",
+			"lambda:UpdateFunctionConfiguration",
+			"lambda:GetFunction",
+			"lambda:AddPermission",
+		}},
+		{actionNames: []string{"s3:PutObject"}, resourceArns: []string{fmt.Sprintf("arn:aws:s3:::%s/*", ctx.s3Bucket)}},
+	}
+	for _, eachRoleArn := range ctx.lambdaIAMRoleNameMap {
+		checks = append(checks, permissionCheck{
+			actionNames:  []string{"iam:PassRole"},
+			resourceArns: []string{eachRoleArn},
+		})
+	}
+
+	iamClient := iam.New(ctx.awsSession)
+	denied := make([]string, 0)
+	for _, eachCheck := range checks {
+		simulateInput := &iam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: aws.String(policySourceArn),
+			ActionNames:     aws.StringSlice(eachCheck.actionNames),
+		}
+		if len(eachCheck.resourceArns) != 0 {
+			simulateInput.ResourceArns = aws.StringSlice(eachCheck.resourceArns)
+		}
+		simulateOutput, err := iamClient.SimulatePrincipalPolicy(simulateInput)
+		if nil != err {
+			// Some principals (e.g. assumed roles without iam:Simulate*
+			// rights) can't be simulated at all - warn and let the real
+			// deploy calls be the source of truth rather than failing outright.
+			ctx.logger.WithFields(logrus.Fields{
+				"ServiceName": ctx.serviceName,
+				"Step":        "CloudFormation",
+			}).Warn("Unable to simulate IAM policy, skipping preflight check: ", err.Error())
+			continue
+		}
+		for _, eachResult := range simulateOutput.EvaluationResults {
+			if *eachResult.EvalDecision != iam.PolicyEvaluationDecisionTypeAllowed {
+				denied = append(denied, fmt.Sprintf("%s (%s): %s",
+					*eachResult.EvalActionName,
+					aws.StringValue(eachResult.EvalResourceName),
+					*eachResult.EvalDecision))
+			}
+		}
 	}
-	awsCloudFormation := cloudformation.New(awsConfig())
+	if len(denied) != 0 {
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"Step":        "CloudFormation",
+		}).Error("Insufficient IAM permissions for deploy: ", strings.Join(denied, "; "))
+		return fmt.Errorf("insufficient IAM permissions for deploy: %s", strings.Join(denied, "; "))
+	}
+	return nil
+}
+
+// iamPolicySourceArn resolves the ARN SimulatePrincipalPolicy actually
+// accepts as a PolicySourceArn. STS returns an assumed-role session ARN
+// (arn:aws:sts::<account>:assumed-role/<role>/<session>) for instance
+// profiles and assumed-role credentials - the CI/CD case this preflight
+// targets - but the simulator only accepts the underlying IAM role ARN, so
+// that form is rewritten to arn:aws:iam::<account>:role/<role>. Any other
+// ARN (e.g. an IAM user) is returned unchanged.
+func iamPolicySourceArn(callerArn string) string {
+	arnParts := strings.SplitN(callerArn, ":", 6)
+	if len(arnParts) != 6 || arnParts[2] != "sts" {
+		return callerArn
+	}
+	resourceParts := strings.SplitN(arnParts[5], "/", 3)
+	if len(resourceParts) < 2 || resourceParts[0] != "assumed-role" {
+		return callerArn
+	}
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", arnParts[1], arnParts[4], resourceParts[1])
+}
+
+func convergeStackState(cfTemplateURL string, ctx *workflowContext) (*cloudformation.Stack, error) {
+
+	deployParameters := []*cloudformation.Parameter{
+		{ParameterKey: aws.String("SpartaLambdaZipKey"), ParameterValue: aws.String(ctx.s3LambdaZipKey)},
+		{ParameterKey: aws.String("SpartaTemplateHash"), ParameterValue: aws.String(ctx.templateHash)},
+	}
+
+	awsCloudFormation := cloudformation.New(ctx.awsSession)
+	describeStacksOutput, describeErr := awsCloudFormation.DescribeStacks(&cloudformation.DescribeStacksInput{
+		StackName: aws.String(ctx.serviceName),
+	})
+	exists := false
+	if nil != describeErr {
+		if !strings.Contains(describeErr.Error(), "does not exist") {
+			return nil, describeErr
+		}
+	} else {
+		exists = true
+	}
+
 	stackID := ""
 	if exists {
+		existingStack := describeStacksOutput.Stacks[0]
+		if deployParametersUnchanged(existingStack.Parameters, deployParameters) {
+			ctx.logger.WithFields(logrus.Fields{
+				"ServiceName": ctx.serviceName,
+				"StackID":     *existingStack.StackId,
+			}).Info("Lambda code and template unchanged, skipping update")
+			return existingStack, nil
+		}
 		// Update stack
 		updateStackInput := &cloudformation.UpdateStackInput{
-			StackName:   aws.String(ctx.serviceName),
-			TemplateURL: aws.String(cfTemplateURL),
+			StackName:    aws.String(ctx.serviceName),
+			TemplateURL:  aws.String(cfTemplateURL),
+			Parameters:   deployParameters,
+			Capabilities: ctx.stackCapabilities,
 		}
 		updateStackResponse, err := awsCloudFormation.UpdateStack(updateStackInput)
 		if nil != err {
 			return nil, err
 		}
-		ctx.logger.Info("Issued update request: ", *updateStackResponse.StackId)
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"StackID":     *updateStackResponse.StackId,
+		}).Info("Issued update request")
 		stackID = *updateStackResponse.StackId
 	} else {
 		// Create stack
@@ -310,48 +547,27 @@ func convergeStackState(cfTemplateURL string, ctx *workflowContext) (*cloudforma
 			TemplateURL:      aws.String(cfTemplateURL),
 			TimeoutInMinutes: aws.Int64(5),
 			OnFailure:        aws.String(cloudformation.OnFailureDelete),
+			Parameters:       deployParameters,
+			Capabilities:     ctx.stackCapabilities,
 		}
 		createStackResponse, err := awsCloudFormation.CreateStack(createStackInput)
 		if nil != err {
 			return nil, err
 		}
-		ctx.logger.Info("Creating stack: ", *createStackResponse.StackId)
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"StackID":     *createStackResponse.StackId,
+		}).Info("Creating stack")
 		stackID = *createStackResponse.StackId
 	}
 
 	// Poll for the current stackID state
-	describeStacksInput := &cloudformation.DescribeStacksInput{
-		StackName: aws.String(stackID),
+	stackInfo, err := pollStackOperationCompletion(stackID, awsCloudFormation, ctx.logger)
+	if nil != err {
+		return nil, err
 	}
-
-	var stackInfo *cloudformation.Stack
-	stackOperationComplete := false
-	ctx.logger.Info("Waiting for stack to complete")
-	for !stackOperationComplete {
-		time.Sleep(10 * time.Second)
-		describeStacksOutput, err := awsCloudFormation.DescribeStacks(describeStacksInput)
-		if nil != err {
-			return nil, err
-		}
-		if len(describeStacksOutput.Stacks) > 0 {
-			stackInfo = describeStacksOutput.Stacks[0]
-			ctx.logger.Info("Current state: ", *stackInfo.StackStatus)
-			switch *stackInfo.StackStatus {
-			case cloudformation.StackStatusCreateInProgress,
-				cloudformation.StackStatusDeleteInProgress,
-				cloudformation.StackStatusUpdateInProgress,
-				cloudformation.StackStatusRollbackInProgress,
-				cloudformation.StackStatusUpdateCompleteCleanupInProgress,
-				cloudformation.StackStatusUpdateRollbackCompleteCleanupInProgress,
-				cloudformation.StackStatusUpdateRollbackInProgress:
-				time.Sleep(20 * time.Second)
-			default:
-				stackOperationComplete = true
-				break
-			}
-		} else {
-			return nil, errors.New("More than one stack returned for: " + stackID)
-		}
+	if nil == stackInfo {
+		return nil, errors.New("Stack disappeared during provisioning: " + ctx.serviceName)
 	}
 	// What happened?
 	succeed := true
@@ -370,17 +586,20 @@ func convergeStackState(cfTemplateURL string, ctx *workflowContext) (*cloudforma
 		if nil != err {
 			return nil, err
 		}
-		ctx.logger.Error("Stack provisioning failed.")
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"StackID":     stackID,
+		}).Error("Stack provisioning failed")
 		for _, eachEvent := range events {
 			switch *eachEvent.ResourceStatus {
 			case cloudformation.ResourceStatusCreateFailed,
 				cloudformation.ResourceStatusDeleteFailed,
 				cloudformation.ResourceStatusUpdateFailed:
-				errMsg := fmt.Sprintf("\tError ensuring %s (%s): %s",
-					*eachEvent.ResourceType,
-					*eachEvent.LogicalResourceId,
-					*eachEvent.ResourceStatusReason)
-				ctx.logger.Error(errMsg)
+				ctx.logger.WithFields(logrus.Fields{
+					"ServiceName":  ctx.serviceName,
+					"ResourceType": *eachEvent.ResourceType,
+					"LogicalID":    *eachEvent.LogicalResourceId,
+				}).Error(*eachEvent.ResourceStatusReason)
 			default:
 				// NOP
 			}
@@ -393,59 +612,102 @@ func convergeStackState(cfTemplateURL string, ctx *workflowContext) (*cloudforma
 
 func ensureCloudFormationStack(s3Key string) workflowStep {
 	return func(ctx *workflowContext) (workflowStep, error) {
-		awsConfig := awsConfig()
-
 		// We're going to create a template that represents the new state of the
 		// lambda world.
-		cloudFormationTemplate := ArbitraryJSONObject{
-			"AWSTemplateFormatVersion": "2010-09-09",
-			"Description":              ctx.serviceDescription,
-		}
-		resources := make(ArbitraryJSONObject, 0)
+		cloudFormationTemplate := NewTemplate(ctx.serviceDescription)
 		for _, eachEntry := range ctx.lambdaAWSInfos {
-			err := eachEntry.toCloudFormationResources(ctx.s3Bucket, s3Key, ctx.lambdaIAMRoleNameMap, resources)
+			err := eachEntry.toCloudFormationResources(ctx.serviceName,
+				ctx.s3Bucket,
+				s3Key,
+				ctx.lambdaIAMRoleNameMap,
+				cloudFormationTemplate,
+				ctx.logger)
 			if nil != err {
 				return nil, err
 			}
 		}
-		cloudFormationTemplate["Resources"] = resources
 
-		// Generate a complete CloudFormation template
-		cfTemplate, err := json.Marshal(cloudFormationTemplate)
+		// Track the uploaded ZIP key as a stack Parameter so a subsequent
+		// deploy can tell, from the template hash alone, whether anything
+		// actually changed.
+		cloudFormationTemplate.AddParameter("SpartaLambdaZipKey", s3Key)
+
+		// Hash the template body before the hash-of-itself Parameter is
+		// added, then record that hash as its own Parameter.
+		prehashTemplate, err := json.Marshal(cloudFormationTemplate)
 		if err != nil {
 			ctx.logger.Error("Failed to Marshal CloudFormation template: ", err.Error())
 			return nil, err
 		}
+		hash := sha256.New()
+		hash.Write(prehashTemplate)
+		ctx.templateHash = hex.EncodeToString(hash.Sum(nil))
+		cloudFormationTemplate.AddParameter("SpartaTemplateHash", ctx.templateHash)
 
-		// Upload the template to S3
-		s3Client := s3.New(awsConfig)
-		uploadOptions := &s3manager.UploadOptions{S3: s3Client}
+		// Generate the final CloudFormation template
+		cfTemplate, err := json.Marshal(cloudFormationTemplate)
+		if err != nil {
+			ctx.logger.Error("Failed to Marshal CloudFormation template: ", err.Error())
+			return nil, err
+		}
+		ctx.templateSizeBytes = int64(len(cfTemplate))
 		contentBody := string(cfTemplate)
 		sanitizedServiceName := sanitizedName(ctx.serviceName)
-		hash := sha1.New()
-		hash.Write([]byte(contentBody))
-		s3keyName := fmt.Sprintf("%s-%s-cf.json", sanitizedServiceName, hex.EncodeToString(hash.Sum(nil)))
-
-		ctx.logger.Info("Uploading CloudFormation template")
-
-		uploadInput := &s3manager.UploadInput{
-			Bucket:      &ctx.s3Bucket,
-			Key:         &s3keyName,
-			ContentType: aws.String("application/json"),
-			Body:        strings.NewReader(contentBody),
+		s3keyName := fmt.Sprintf("%s-sha256-%s-cf.json", sanitizedServiceName, ctx.templateHash)
+
+		s3Client := s3.New(ctx.awsSession)
+		var templateURL string
+		_, headErr := s3Client.HeadObject(&s3.HeadObjectInput{
+			Bucket: aws.String(ctx.s3Bucket),
+			Key:    aws.String(s3keyName),
+		})
+		if nil == headErr {
+			ctx.logger.WithFields(logrus.Fields{
+				"ServiceName": ctx.serviceName,
+				"Step":        "CloudFormation",
+				"S3Key":       s3keyName,
+			}).Info("CloudFormation template unchanged, skipping upload")
+			templateURL = fmt.Sprintf("https://s3.amazonaws.com/%s/%s", ctx.s3Bucket, s3keyName)
+		} else {
+			ctx.logger.WithFields(logrus.Fields{
+				"ServiceName":   ctx.serviceName,
+				"Step":          "CloudFormation",
+				"S3Key":         s3keyName,
+				"TemplateBytes": ctx.templateSizeBytes,
+			}).Info("Uploading CloudFormation template")
+
+			uploadOptions := &s3manager.UploadOptions{S3: s3Client}
+			uploadInput := &s3manager.UploadInput{
+				Bucket:      &ctx.s3Bucket,
+				Key:         &s3keyName,
+				ContentType: aws.String("application/json"),
+				Body:        strings.NewReader(contentBody),
+			}
+			ctx.logger.Debug("Cloudformation template:\n", contentBody)
+			uploader := s3manager.NewUploader(uploadOptions)
+			templateUploadResult, err := uploader.Upload(uploadInput)
+			if nil != err {
+				return nil, err
+			}
+			ctx.logger.WithFields(logrus.Fields{
+				"ServiceName": ctx.serviceName,
+				"Step":        "CloudFormation",
+			}).Info("CloudFormation template uploaded: ", templateUploadResult.Location)
+			templateURL = templateUploadResult.Location
 		}
-		ctx.logger.Debug("Cloudformation template:\n", contentBody)
-		uploader := s3manager.NewUploader(uploadOptions)
-		templateUploadResult, err := uploader.Upload(uploadInput)
-		if nil != err {
+
+		if err := validateTemplateAndPermissions(templateURL, ctx); nil != err {
 			return nil, err
 		}
-		ctx.logger.Info("CloudFormation template uploaded: ", templateUploadResult.Location)
-		stack, err := convergeStackState(templateUploadResult.Location, ctx)
+
+		stack, err := convergeStackState(templateURL, ctx)
 		if nil != err {
 			return nil, err
 		}
-		ctx.logger.Info("Stack provisioned: ", stack)
+		ctx.logger.WithFields(logrus.Fields{
+			"ServiceName": ctx.serviceName,
+			"StackID":     *stack.StackId,
+		}).Info("Stack provisioned")
 		return nil, nil
 	}
 }
@@ -468,21 +730,23 @@ func ensureCloudFormationStack(s3Key string) workflowStep {
 //  https://docs.google.com/document/d/1Bz5-UB7g2uPBdOx-rw5t9MxJwkfpx90cqG9AFL0JAYo/edit
 //  https://medium.com/@freeformz/go-1-5-s-vendor-experiment-fd3e830f52c3#.voiicue1j
 //
-func Provision(serviceName string, serviceDescription string, lambdaAWSInfos []*LambdaAWSInfo, s3Bucket string, logger *logrus.Logger) error {
+func Provision(serviceName string, serviceDescription string, lambdaAWSInfos []*LambdaAWSInfo, s3Bucket string, options *ProvisionOptions, logger *logrus.Logger) error {
 	ctx := &workflowContext{
 		serviceName:        serviceName,
 		serviceDescription: serviceDescription,
 		lambdaAWSInfos:     lambdaAWSInfos,
 		s3Bucket:           s3Bucket,
-		logger:             logger}
+		logger:             logger,
+		awsSession:         awsSession(options, logger)}
 
-	for step := verifyIAMRoles; step != nil; {
+	provisionStart := time.Now()
+	for step := stepTimer("VerifyIAMRoles", verifyIAMRoles); step != nil; {
 		next, err := step(ctx)
 		if err != nil {
 			ctx.logger.Error(err.Error())
-			if "" != ctx.s3LambdaZipKey {
+			if ctx.s3LambdaZipKeyUploaded {
 				ctx.logger.Info("Attempting to cleanup ZIP archive: ", ctx.s3LambdaZipKey)
-				s3Client := s3.New(awsConfig())
+				s3Client := s3.New(ctx.awsSession)
 				params := &s3.DeleteObjectInput{
 					Bucket: aws.String(ctx.s3Bucket),
 					Key:    aws.String(ctx.s3LambdaZipKey),
@@ -500,5 +764,18 @@ func Provision(serviceName string, serviceDescription string, lambdaAWSInfos []*
 			step = next
 		}
 	}
+
+	totalDurationMS := time.Since(provisionStart).Nanoseconds() / int64(time.Millisecond)
+	summaryFields := logrus.Fields{
+		"ServiceName":     ctx.serviceName,
+		"TotalDurationMS": totalDurationMS,
+		"BinarySizeBytes": ctx.binarySizeBytes,
+		"TemplateBytes":   ctx.templateSizeBytes,
+	}
+	for _, eachStep := range ctx.stepDurations {
+		summaryFields[eachStep.Step+"DurationMS"] = eachStep.DurationMS
+	}
+	ctx.logger.WithFields(summaryFields).Info("Provision complete")
+	publishDeployMetrics(ctx, totalDurationMS)
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// +build !lambdabinary
+
+package sparta
+
+import (
+	"os"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// ProvisionOptions controls how Provision authenticates to AWS. The zero
+// value (or a nil *ProvisionOptions) uses the default shared-credentials
+// chain: environment variables, then the AWS_PROFILE shared credentials
+// file, then EC2 instance role credentials.
+type ProvisionOptions struct {
+	// CredentialsProvider, when set, is used instead of the default
+	// credentials chain. This lets tests inject a fake/mock provider for
+	// the whole workflow, and lets callers wire up an assume-role chain of
+	// their own.
+	CredentialsProvider credentials.Provider
+}
+
+// awsRegion returns the region Sparta should operate in, defaulting to
+// us-east-1 when AWS_DEFAULT_REGION isn't set.
+func awsRegion() string {
+	region := os.Getenv("AWS_DEFAULT_REGION")
+	if "" == region {
+		region = "us-east-1"
+	}
+	return region
+}
+
+// awsSession returns the shared session.Session every AWS service client in
+// a workflow is built from, so a single set of resolved credentials (and a
+// single connection pool) is reused across the whole Provision/Delete/
+// Describe run rather than re-resolved on every client construction.
+func awsSession(options *ProvisionOptions, logger *logrus.Logger) *session.Session {
+	awsConfig := aws.NewConfig().WithRegion(awsRegion()).WithMaxRetries(3)
+
+	if nil != options && nil != options.CredentialsProvider {
+		awsConfig = awsConfig.WithCredentials(credentials.NewCredentials(options.CredentialsProvider))
+	} else {
+		defaultSession := session.New()
+		awsConfig = awsConfig.WithCredentials(credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvProvider{},
+			&credentials.SharedCredentialsProvider{Profile: os.Getenv("AWS_PROFILE")},
+			&ec2rolecreds.EC2RoleProvider{Client: ec2metadata.New(defaultSession)},
+		}))
+	}
+	logger.WithFields(logrus.Fields{
+		"Region": *awsConfig.Region,
+	}).Debug("Creating AWS session")
+	return session.New(awsConfig)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sparta
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+)
+
+// pollStackOperationCompletion polls DescribeStacks for stackID until it
+// leaves one of the CloudFormation *_IN_PROGRESS states, and is shared by
+// every workflow (Provision, Delete) that has to wait out a stack operation.
+// A stack that disappears entirely while being polled (the terminal state of
+// a successful delete) is reported as a nil *cloudformation.Stack.
+func pollStackOperationCompletion(stackID string,
+	awsCloudFormation *cloudformation.CloudFormation,
+	logger *logrus.Logger) (*cloudformation.Stack, error) {
+
+	describeStacksInput := &cloudformation.DescribeStacksInput{
+		StackName: aws.String(stackID),
+	}
+
+	logger.Info("Waiting for stack to complete")
+	for {
+		time.Sleep(10 * time.Second)
+		describeStacksOutput, err := awsCloudFormation.DescribeStacks(describeStacksInput)
+		if nil != err {
+			if strings.Contains(err.Error(), "does not exist") {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if len(describeStacksOutput.Stacks) == 0 {
+			return nil, nil
+		}
+		stackInfo := describeStacksOutput.Stacks[0]
+		logger.Info("Current state: ", *stackInfo.StackStatus)
+		switch *stackInfo.StackStatus {
+		case cloudformation.StackStatusCreateInProgress,
+			cloudformation.StackStatusDeleteInProgress,
+			cloudformation.StackStatusUpdateInProgress,
+			cloudformation.StackStatusRollbackInProgress,
+			cloudformation.StackStatusUpdateCompleteCleanupInProgress,
+			cloudformation.StackStatusUpdateRollbackCompleteCleanupInProgress,
+			cloudformation.StackStatusUpdateRollbackInProgress:
+			time.Sleep(20 * time.Second)
+		default:
+			return stackInfo, nil
+		}
+	}
+}
+
+// stackArtifactKeys inspects the AWS::Lambda::Function resources in a
+// stack's current template and returns the S3 bucket and keys Sparta
+// uploaded for them, so callers can garbage collect those artifacts once the
+// stack referencing them is gone.
+func stackArtifactKeys(stackID string,
+	awsCloudFormation *cloudformation.CloudFormation) (string, []string, error) {
+
+	templateOutput, err := awsCloudFormation.GetTemplate(&cloudformation.GetTemplateInput{
+		StackName: aws.String(stackID),
+	})
+	if nil != err {
+		return "", nil, err
+	}
+
+	var rawTemplate struct {
+		Resources map[string]struct {
+			Type       string
+			Properties struct {
+				Code struct {
+					S3Bucket string
+					S3Key    string
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(*templateOutput.TemplateBody), &rawTemplate); nil != err {
+		return "", nil, err
+	}
+
+	s3Bucket := ""
+	s3Keys := make([]string, 0)
+	for _, eachResource := range rawTemplate.Resources {
+		if eachResource.Type != "AWS::Lambda::Function" {
+			continue
+		}
+		s3Bucket = eachResource.Properties.Code.S3Bucket
+		s3Keys = append(s3Keys, eachResource.Properties.Code.S3Key)
+	}
+	return s3Bucket, s3Keys, nil
+}
+
+// stackTemplateArtifactKey reconstructs the S3 key of the CloudFormation
+// template uploaded for stackParameters' stack, mirroring the naming
+// ensureCloudFormationStack uses when it uploads the template, so callers
+// can garbage collect it alongside the Lambda ZIP. Returns "" if the stack
+// predates the SpartaTemplateHash parameter.
+func stackTemplateArtifactKey(serviceName string, stackParameters []*cloudformation.Parameter) string {
+	for _, eachParameter := range stackParameters {
+		if nil != eachParameter.ParameterKey && "SpartaTemplateHash" == *eachParameter.ParameterKey {
+			return fmt.Sprintf("%s-sha256-%s-cf.json", sanitizedName(serviceName), *eachParameter.ParameterValue)
+		}
+	}
+	return ""
+}
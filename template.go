@@ -0,0 +1,157 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sparta
+
+import "encoding/json"
+
+// RefFunc is the typed form of the CloudFormation {"Ref": "..."} intrinsic.
+type RefFunc struct {
+	Name string `json:"-"`
+}
+
+// MarshalJSON renders the intrinsic in the shape CloudFormation expects.
+func (ref RefFunc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"Ref": ref.Name})
+}
+
+// Ref returns a typed reference to another resource or parameter's logical name.
+func Ref(logicalName string) RefFunc {
+	return RefFunc{Name: logicalName}
+}
+
+// GetAttFunc is the typed form of the CloudFormation {"Fn::GetAtt": [...]} intrinsic.
+type GetAttFunc struct {
+	LogicalName string `json:"-"`
+	Attribute   string `json:"-"`
+}
+
+// MarshalJSON renders the intrinsic in the shape CloudFormation expects.
+func (getAtt GetAttFunc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string][]string{
+		"Fn::GetAtt": {getAtt.LogicalName, getAtt.Attribute},
+	})
+}
+
+// GetAtt returns a typed reference to an attribute of another resource.
+func GetAtt(logicalName string, attribute string) GetAttFunc {
+	return GetAttFunc{LogicalName: logicalName, Attribute: attribute}
+}
+
+// JoinFunc is the typed form of the CloudFormation {"Fn::Join": [delim, [...]]} intrinsic.
+type JoinFunc struct {
+	Delimiter string        `json:"-"`
+	Values    []interface{} `json:"-"`
+}
+
+// MarshalJSON renders the intrinsic in the shape CloudFormation expects.
+func (join JoinFunc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"Fn::Join": []interface{}{join.Delimiter, join.Values},
+	})
+}
+
+// Join returns a typed Fn::Join of the given values using delimiter.
+func Join(delimiter string, values ...interface{}) JoinFunc {
+	return JoinFunc{Delimiter: delimiter, Values: values}
+}
+
+// ResourceProperties is implemented by every typed CloudFormation resource
+// property bag (LambdaFunctionProperties, IAMRoleProperties, ...) so that a
+// Resource can hold any of them while still knowing its own "Type".
+type ResourceProperties interface {
+	CloudFormationType() string
+}
+
+// Resource is a typed CloudFormation template resource: a Type discriminator
+// derived from its Properties, plus the handful of resource attributes
+// Sparta itself needs to set.
+type Resource struct {
+	Properties ResourceProperties
+	DependsOn  []string `json:",omitempty"`
+}
+
+// MarshalJSON renders the resource as the "Type"/"Properties" shape CloudFormation expects.
+func (resource *Resource) MarshalJSON() ([]byte, error) {
+	rendered := ArbitraryJSONObject{
+		"Type":       resource.Properties.CloudFormationType(),
+		"Properties": resource.Properties,
+	}
+	if len(resource.DependsOn) != 0 {
+		rendered["DependsOn"] = resource.DependsOn
+	}
+	return json.Marshal(rendered)
+}
+
+// Output is a typed CloudFormation template Output entry.
+type Output struct {
+	Description string
+	Value       interface{}
+}
+
+// Template is the typed replacement for the ArbitraryJSONObject map that
+// ensureCloudFormationStack used to assemble by hand.
+type Template struct {
+	AWSTemplateFormatVersion string
+	Description              string
+	Parameters               ArbitraryJSONObject `json:",omitempty"`
+	Mappings                 ArbitraryJSONObject `json:",omitempty"`
+	Resources                map[string]*Resource
+	Outputs                  map[string]*Output `json:",omitempty"`
+}
+
+// NewTemplate returns an empty typed Template ready to have resources added to it.
+func NewTemplate(description string) *Template {
+	return &Template{
+		AWSTemplateFormatVersion: "2010-09-09",
+		Description:              description,
+		Resources:                make(map[string]*Resource),
+	}
+}
+
+// AddResource registers a typed resource under the given CloudFormation logical ID.
+func (template *Template) AddResource(logicalName string, properties ResourceProperties) *Resource {
+	resource := &Resource{Properties: properties}
+	template.Resources[logicalName] = resource
+	return resource
+}
+
+// AddParameter registers a String CloudFormation Parameter with the given
+// default value. Sparta uses this to record content hashes (the uploaded
+// ZIP key, the template's own hash) as stack Parameters purely so a
+// subsequent deploy can diff them against the live stack and skip a no-op
+// UpdateStack call.
+func (template *Template) AddParameter(name string, defaultValue string) {
+	if template.Parameters == nil {
+		template.Parameters = make(ArbitraryJSONObject)
+	}
+	template.Parameters[name] = ArbitraryJSONObject{
+		"Type":    "String",
+		"Default": defaultValue,
+	}
+}
+
+// AddOutput registers a typed Output under the given name.
+func (template *Template) AddOutput(name string, description string, value interface{}) {
+	if template.Outputs == nil {
+		template.Outputs = make(map[string]*Output)
+	}
+	template.Outputs[name] = &Output{Description: description, Value: value}
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2015 Matt Weagle <mweagle@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package sparta
+
+import "strings"
+
+// ArbitraryJSONObject represents a loosely typed JSON blob used where a
+// fully typed CloudFormation structure doesn't (yet) exist, such as IAM
+// policy documents and EventBridge event patterns.
+type ArbitraryJSONObject map[string]interface{}
+
+// sanitizedName strips the '-', '.' and '_' characters from name so it can
+// serve as a CloudFormation logical resource ID and a NodeJS proxy function
+// name. No other characters are removed.
+func sanitizedName(name string) string {
+	replacer := strings.NewReplacer("-", "", ".", "", "_", "")
+	return replacer.Replace(name)
+}